@@ -0,0 +1,166 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveClusterFilters lists every NodeAgentClusterFilter selected by the NodeAgent's
+// FilterSelector, ordered by Spec.Priority (lowest first) so rendering is deterministic.
+func (n *nodeAgentInstance) resolveClusterFilters(ctx context.Context) ([]v1beta1.NodeAgentClusterFilter, error) {
+	selector, err := clusterResourceSelector(n.nodeAgent.FilterSelector)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid NodeAgent.FilterSelector")
+	}
+
+	var list v1beta1.NodeAgentClusterFilterList
+	if err := n.client.List(ctx, &list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.WrapIf(err, "failed to list NodeAgentClusterFilter resources")
+	}
+
+	filters := list.Items
+	sortClusterFiltersByPriority(filters)
+
+	return filters, nil
+}
+
+// sortClusterFiltersByPriority orders filters by Spec.Priority, lowest first, stably so
+// equal-priority filters keep the order the API server returned them in.
+func sortClusterFiltersByPriority(filters []v1beta1.NodeAgentClusterFilter) {
+	sort.SliceStable(filters, func(i, j int) bool {
+		return filters[i].Spec.Priority < filters[j].Spec.Priority
+	})
+}
+
+// resolveClusterParsers lists every NodeAgentClusterParser selected by the NodeAgent's
+// ParserSelector.
+func (n *nodeAgentInstance) resolveClusterParsers(ctx context.Context) ([]v1beta1.NodeAgentClusterParser, error) {
+	selector, err := clusterResourceSelector(n.nodeAgent.ParserSelector)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid NodeAgent.ParserSelector")
+	}
+
+	var list v1beta1.NodeAgentClusterParserList
+	if err := n.client.List(ctx, &list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.WrapIf(err, "failed to list NodeAgentClusterParser resources")
+	}
+
+	return list.Items, nil
+}
+
+func clusterResourceSelector(sel *metav1.LabelSelector) (labels.Selector, error) {
+	if sel == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(sel)
+}
+
+// matchesNamespace applies a NodeAgentClusterFilter's namespace scoping: Namespaces (if
+// set, only these namespaces qualify) followed by ExcludeNamespaces (always removes
+// these), so a filter can be scoped both "only these teams' namespaces" and "except this
+// noisy one".
+func matchesNamespace(namespaces, excludeNamespaces []string, namespace string) bool {
+	if len(namespaces) > 0 && !containsString(namespaces, namespace) {
+		return false
+	}
+	return !containsString(excludeNamespaces, namespace)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// renderClusterConfig resolves the NodeAgentClusterParser/NodeAgentClusterFilter resources
+// selected by this NodeAgent and renders them into the `[PARSER]`/`[FILTER]` stanzas that
+// renderConfigs stores beside the runtime's own configuration. namespace scopes which
+// filters apply: it's the Logging CR's own control namespace, so a cluster filter's
+// Namespaces/ExcludeNamespaces restricts which Logging instances may use it.
+func (n *nodeAgentInstance) renderClusterConfig(ctx context.Context, namespace string) (string, error) {
+	parsers, err := n.resolveClusterParsers(ctx)
+	if err != nil {
+		return "", err
+	}
+	filters, err := n.resolveClusterFilters(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return renderFilterStanzas(parsers, filters, namespace)
+}
+
+// renderFilterStanzas renders the `[PARSER]`/`[FILTER]` stanzas for the given cluster
+// resources, scoped to namespace, validating that every parser referenced by a filter that
+// applies to namespace actually exists before anything is written to the secret; filters
+// scoped out of namespace are skipped without that check. Split out from renderClusterConfig so the
+// rendering logic is testable without a client.
+func renderFilterStanzas(parsers []v1beta1.NodeAgentClusterParser, filters []v1beta1.NodeAgentClusterFilter, namespace string) (string, error) {
+	knownParsers := make(map[string]bool, len(parsers))
+	var sb strings.Builder
+	for _, parser := range parsers {
+		knownParsers[parser.Spec.Name] = true
+		fmt.Fprintf(&sb, "[PARSER]\n    Name %s\n    Format %s\n\n", parser.Spec.Name, parser.Spec.Format)
+	}
+
+	for _, filter := range filters {
+		if !matchesNamespace(filter.Spec.Namespaces, filter.Spec.ExcludeNamespaces, namespace) {
+			continue
+		}
+		if filter.Spec.ParserName != "" && !knownParsers[filter.Spec.ParserName] {
+			return "", errors.Errorf(
+				"NodeAgentClusterFilter %q references unknown parser %q", filter.Name, filter.Spec.ParserName)
+		}
+		fmt.Fprintf(&sb, "[FILTER]\n    Name %s\n    Match %s\n\n", filter.Spec.Name, filter.Spec.Match)
+	}
+
+	return sb.String(), nil
+}
+
+// renderConfigs computes this NodeAgent's config sections: the active runtime's own
+// RenderConfig output plus the cluster-scoped filter/parser stanzas resolved above. The
+// result is stored on n.configs for configSecret to assemble into the rendered secret.
+func (n *nodeAgentInstance) renderConfigs(ctx context.Context) error {
+	runtimeConfig, err := n.runtime.RenderConfig(n.nodeAgent)
+	if err != nil {
+		return errors.WrapIf(err, "failed to render runtime config")
+	}
+
+	clusterConfig, err := n.renderClusterConfig(ctx, n.logging.Spec.ControlNamespace)
+	if err != nil {
+		return errors.WrapIf(err, "failed to render cluster filter/parser config")
+	}
+
+	if n.configs == nil {
+		n.configs = make(map[string][]byte)
+	}
+	n.configs["runtime.conf"] = []byte(runtimeConfig)
+	n.configs["cluster.conf"] = []byte(clusterConfig)
+
+	return nil
+}
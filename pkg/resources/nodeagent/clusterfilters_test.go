@@ -0,0 +1,103 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchesNamespace(t *testing.T) {
+	cases := []struct {
+		name              string
+		namespaces        []string
+		excludeNamespaces []string
+		namespace         string
+		want              bool
+	}{
+		{name: "no restriction matches everything", namespace: "team-a", want: true},
+		{name: "allow-list matches listed namespace", namespaces: []string{"team-a"}, namespace: "team-a", want: true},
+		{name: "allow-list rejects unlisted namespace", namespaces: []string{"team-a"}, namespace: "team-b", want: false},
+		{name: "exclude-list rejects excluded namespace", excludeNamespaces: []string{"noisy"}, namespace: "noisy", want: false},
+		{name: "exclude wins even if allow-listed", namespaces: []string{"team-a"}, excludeNamespaces: []string{"team-a"}, namespace: "team-a", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matchesNamespace(tc.namespaces, tc.excludeNamespaces, tc.namespace); got != tc.want {
+				t.Errorf("matchesNamespace() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSortClusterFiltersByPriority(t *testing.T) {
+	filters := []v1beta1.NodeAgentClusterFilter{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: v1beta1.NodeAgentClusterFilterSpec{Priority: 20}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: v1beta1.NodeAgentClusterFilterSpec{Priority: 10}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}, Spec: v1beta1.NodeAgentClusterFilterSpec{Priority: 10}},
+	}
+
+	sortClusterFiltersByPriority(filters)
+
+	got := []string{filters[0].Name, filters[1].Name, filters[2].Name}
+	want := []string{"a", "c", "b"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sortClusterFiltersByPriority() order = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestRenderFilterStanzas(t *testing.T) {
+	parsers := []v1beta1.NodeAgentClusterParser{
+		{Spec: v1beta1.NodeAgentClusterParserSpec{Name: "json-parser", Format: "json"}},
+	}
+
+	t.Run("renders parsers and filters scoped to namespace", func(t *testing.T) {
+		filters := []v1beta1.NodeAgentClusterFilter{
+			{Spec: v1beta1.NodeAgentClusterFilterSpec{Name: "kube-meta", Match: "kube.*", ParserName: "json-parser", Namespaces: []string{"team-a"}}},
+			{Spec: v1beta1.NodeAgentClusterFilterSpec{Name: "other-team", Match: "kube.*", Namespaces: []string{"team-b"}}},
+		}
+
+		config, err := renderFilterStanzas(parsers, filters, "team-a")
+		if err != nil {
+			t.Fatalf("renderFilterStanzas() error = %v", err)
+		}
+		if !strings.Contains(config, "[PARSER]") || !strings.Contains(config, "Name json-parser") {
+			t.Errorf("expected rendered [PARSER] stanza, got:\n%s", config)
+		}
+		if !strings.Contains(config, "Name kube-meta") {
+			t.Errorf("expected the team-a scoped filter to be rendered, got:\n%s", config)
+		}
+		if strings.Contains(config, "Name other-team") {
+			t.Errorf("expected the team-b scoped filter to be excluded, got:\n%s", config)
+		}
+	})
+
+	t.Run("errors on unknown parser reference", func(t *testing.T) {
+		filters := []v1beta1.NodeAgentClusterFilter{
+			{ObjectMeta: metav1.ObjectMeta{Name: "broken"}, Spec: v1beta1.NodeAgentClusterFilterSpec{Name: "broken", Match: "kube.*", ParserName: "missing"}},
+		}
+
+		if _, err := renderFilterStanzas(nil, filters, ""); err == nil {
+			t.Fatal("expected an error for a filter referencing an unknown parser")
+		}
+	})
+}
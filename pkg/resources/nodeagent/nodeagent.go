@@ -15,6 +15,7 @@
 package nodeagent
 
 import (
+	"context"
 	"fmt"
 
 	"emperror.dev/errors"
@@ -165,11 +166,23 @@ func (n *nodeAgentInstance) getFluentBitLabels() map[string]string {
 	}, generateLoggingRefLabels(n.logging.ObjectMeta.GetName()))
 }
 
+// getRuntimeLabels is like getFluentBitLabels but scoped by the active runtime's name, so a
+// vector/fluentd-forwarder/ebpf NodeAgent's own pods are selected instead of fluent-bit's.
+func (n *nodeAgentInstance) getRuntimeLabels() map[string]string {
+	return util.MergeLabels(n.nodeAgent.Metadata.Labels, map[string]string{
+		"app.kubernetes.io/name":     n.runtime.Name(),
+		"app.kubernetes.io/instance": n.nodeAgent.Name,
+	}, generateLoggingRefLabels(n.logging.ObjectMeta.GetName()))
+}
+
+// getServiceAccount returns the service account this NodeAgent's pods should run as. Only
+// the fluentbit runtime currently exposes a Security.ServiceAccount override; FluentbitSpec
+// is nil for every other runtime, so it must be checked before being dereferenced.
 func (n *nodeAgentInstance) getServiceAccount() string {
-	if n.nodeAgent.FluentbitSpec.Security.ServiceAccount != "" {
-		return n.nodeAgent.FluentbitSpec.Security.ServiceAccount
+	if spec := n.nodeAgent.FluentbitSpec; spec != nil && spec.Security != nil && spec.Security.ServiceAccount != "" {
+		return spec.Security.ServiceAccount
 	}
-	return n.QualifiedName(defaultServiceAccountName)
+	return n.QualifiedName(n.runtime.Name())
 }
 
 //
@@ -182,6 +195,7 @@ func (n *nodeAgentInstance) getServiceAccount() string {
 type Reconciler struct {
 	Logging *v1beta1.Logging
 	*reconciler.GenericResourceReconciler
+	client  client.Client
 	configs map[string][]byte
 }
 
@@ -190,6 +204,7 @@ func New(client client.Client, logger logr.Logger, logging *v1beta1.Logging, opt
 	return &Reconciler{
 		Logging:                   logging,
 		GenericResourceReconciler: reconciler.NewGenericReconciler(client, logger, opts),
+		client:                    client,
 	}
 }
 
@@ -198,39 +213,49 @@ type nodeAgentInstance struct {
 	reconciler *reconciler.GenericResourceReconciler
 	logging    *v1beta1.Logging
 	configs    map[string][]byte
+	runtime    NodeAgentRuntime
+	client     client.Client
 }
 
 // Reconcile reconciles the NodeAgent resource
 func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 	for _, a := range r.Logging.Spec.NodeAgents {
 		var instance nodeAgentInstance
-		err := mergo.Merge(a, NodeAgentFluentbitDefaults())
+
+		runtime, err := nodeAgentRuntimeFor(a.Runtime)
+		if err != nil {
+			return nil, errors.WrapWithDetails(err,
+				"failed to resolve node agent runtime", "NodeName", a.Name)
+		}
+
+		err = mergo.Merge(a, runtime.Defaults())
 		if err != nil {
 			return nil, err
 		}
 
-		switch a.Type {
-		case "windows":
-			err := mergo.Merge(a, NodeAgentFluentbitWindowsDefaults)
-			if err != nil {
-				return nil, err
-			}
-			instance = nodeAgentInstance{
-				nodeAgent:  a,
-				reconciler: r.GenericResourceReconciler,
-				logging:    r.Logging,
-			}
-		default:
-			err := mergo.Merge(a, NodeAgentFluentbitLinuxDefaults)
-			if err != nil {
-				return nil, err
-			}
-			instance = nodeAgentInstance{
-				nodeAgent:  a,
-				reconciler: r.GenericResourceReconciler,
-				logging:    r.Logging,
+		// The windows/linux OS split only matters for the fluent-bit runtime today: it
+		// picks the container mount path and tolerations for the node's OS.
+		if _, isFluentbit := runtime.(fluentbitRuntime); isFluentbit {
+			switch a.Type {
+			case "windows":
+				err := mergo.Merge(a, NodeAgentFluentbitWindowsDefaults)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				err := mergo.Merge(a, NodeAgentFluentbitLinuxDefaults)
+				if err != nil {
+					return nil, err
+				}
 			}
+		}
 
+		instance = nodeAgentInstance{
+			nodeAgent:  a,
+			reconciler: r.GenericResourceReconciler,
+			logging:    r.Logging,
+			runtime:    runtime,
+			client:     r.client,
 		}
 
 		result, err := instance.Reconcile()
@@ -247,6 +272,16 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 
 // Reconcile reconciles the nodeAgentInstance resource
 func (n *nodeAgentInstance) Reconcile() (*reconcile.Result, error) {
+	if err := n.renderConfigs(context.TODO()); err != nil {
+		return nil, errors.WrapIf(err, "failed to render node agent config")
+	}
+
+	if _, isFluentbit := n.runtime.(fluentbitRuntime); isFluentbit {
+		if err := n.applyReloadSettings(); err != nil {
+			return nil, errors.WrapIf(err, "failed to apply reload settings")
+		}
+	}
+
 	for _, factory := range []resources.Resource{
 		n.serviceAccount,
 		n.clusterRole,
@@ -276,6 +311,10 @@ func (n *nodeAgentInstance) Reconcile() (*reconcile.Result, error) {
 		}
 	}
 
+	if err := n.syncStatus(context.TODO()); err != nil {
+		return nil, errors.WrapIf(err, "failed to sync node agent status")
+	}
+
 	return nil, nil
 }
 
@@ -0,0 +1,65 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+)
+
+func TestGetServiceAccount(t *testing.T) {
+	cases := []struct {
+		name      string
+		runtime   NodeAgentRuntime
+		nodeAgent *v1beta1.NodeAgent
+		want      string
+	}{
+		{
+			name:      "fluentbit with an explicit override",
+			runtime:   fluentbitRuntime{},
+			nodeAgent: &v1beta1.NodeAgent{FluentbitSpec: &v1beta1.NodeAgentFluentbit{Security: &v1beta1.Security{ServiceAccount: "custom-sa"}}},
+			want:      "custom-sa",
+		},
+		{
+			name:      "fluentbit without an override falls back to the qualified default",
+			runtime:   fluentbitRuntime{},
+			nodeAgent: &v1beta1.NodeAgent{FluentbitSpec: &v1beta1.NodeAgentFluentbit{}},
+			want:      "logging-agent-fluentbit",
+		},
+		{
+			name:      "vector has no FluentbitSpec and must not panic",
+			runtime:   vectorRuntime{},
+			nodeAgent: &v1beta1.NodeAgent{VectorSpec: &v1beta1.NodeAgentVector{}},
+			want:      "logging-agent-vector",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.nodeAgent.Name = "agent"
+			n := &nodeAgentInstance{
+				nodeAgent: tc.nodeAgent,
+				logging:   &v1beta1.Logging{},
+				runtime:   tc.runtime,
+			}
+			n.logging.Name = "logging"
+
+			if got := n.getServiceAccount(); got != tc.want {
+				t.Errorf("getServiceAccount() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	reloaderContainerName = "fluentbit-reloader"
+
+	// configHashAnnotation records the rendered config's hash on the pod template. It is
+	// only ever set in Reload.Mode "restart" (the default): that's what makes a changed
+	// config roll the DaemonSet the same way a changed image or resource request does.
+	configHashAnnotation = "logging.banzaicloud.io/config-hash"
+)
+
+// reloadMode returns the effective reload mode for a fluent-bit NodeAgent, defaulting to
+// "restart" so existing specs keep today's behavior.
+func reloadMode(spec *v1beta1.NodeAgentFluentbit) v1beta1.ReloadMode {
+	if spec.Reload == nil || spec.Reload.Mode == "" {
+		return v1beta1.ReloadModeRestart
+	}
+	return spec.Reload.Mode
+}
+
+// configHash returns a short, stable hash of the rendered config. It backs the pod
+// template annotation in "restart" mode; "signal"/"sidecar" mode does not use it.
+func configHash(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// podTemplateAnnotations returns the annotations the DaemonSet's pod template should carry
+// for the given rendered config. applyReloadSettings merges them into the DaemonSetOverrides
+// pod template. In "restart" mode this
+// includes the config hash so a changed config rolls every pod, same as today. In
+// "signal"/"sidecar" mode the hash is deliberately left off the pod template to suppress
+// that rollout and preserve in-flight buffers; the reload is instead driven by the signal
+// endpoint or the reloader sidecar.
+func podTemplateAnnotations(spec *v1beta1.NodeAgentFluentbit, config []byte) map[string]string {
+	if reloadMode(spec) != v1beta1.ReloadModeRestart {
+		return nil
+	}
+	return map[string]string{
+		configHashAnnotation: configHash(config),
+	}
+}
+
+// reloaderSidecar builds the container appended to the DaemonSet's pod template when
+// Reload.Mode is "sidecar": it watches the mounted config secret via inotify and POSTs to
+// fluent-bit's /api/v2/reload endpoint on change.
+func reloaderSidecar(spec *v1beta1.NodeAgentFluentbit) v1.Container {
+	return v1.Container{
+		Name:            reloaderContainerName,
+		Image:           "banzaicloud/fluent-bit-reloader:0.1.0",
+		ImagePullPolicy: v1.PullIfNotPresent,
+		Args: []string{
+			"--watch-dir=/fluent-bit/etc",
+			"--reload-url=http://localhost:2020/api/v2/reload",
+		},
+	}
+}
+
+// concatenatedConfigBytes concatenates n.configs in a stable order (sorted by key) so the
+// hash doesn't change from one reconcile to the next just because map iteration order did.
+func concatenatedConfigBytes(configs map[string][]byte) []byte {
+	keys := make([]string, 0, len(configs))
+	for key := range configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		buf.Write(configs[key])
+	}
+	return buf.Bytes()
+}
+
+// applyReloadSettings mutates a fluent-bit NodeAgent's DaemonSetOverrides pod template
+// according to its effective Reload.Mode: "restart" stamps the config-hash annotation so a
+// changed config still rolls every pod; "sidecar" additionally appends the reloader
+// container; "signal" leaves the pod template annotation off so reload doesn't also trigger
+// a rollout. The hash is taken over the rendered config bytes renderConfigs already computed
+// (n.configs), not the NodeAgentFluentbit spec, so edits to a shared NodeAgentClusterFilter/
+// NodeAgentClusterParser roll the pods too, not just edits to the NodeAgent's own spec.
+func (n *nodeAgentInstance) applyReloadSettings() error {
+	spec := n.nodeAgent.FluentbitSpec
+	if spec == nil {
+		return nil
+	}
+
+	config := concatenatedConfigBytes(n.configs)
+
+	if spec.DaemonSetOverrides == nil {
+		spec.DaemonSetOverrides = &typeoverride.DaemonSet{}
+	}
+	tmpl := &spec.DaemonSetOverrides.Spec.Template
+	tmpl.Annotations = util.MergeLabels(tmpl.Annotations, podTemplateAnnotations(spec, config))
+
+	if reloadMode(spec) == v1beta1.ReloadModeSidecar {
+		tmpl.Spec.Containers = append(tmpl.Spec.Containers, reloaderSidecar(spec))
+	}
+
+	return nil
+}
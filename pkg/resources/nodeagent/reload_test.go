@@ -0,0 +1,103 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+)
+
+func TestPodTemplateAnnotations(t *testing.T) {
+	cases := []struct {
+		name string
+		mode v1beta1.ReloadMode
+		want bool
+	}{
+		{name: "restart stamps the config hash", mode: v1beta1.ReloadModeRestart, want: true},
+		{name: "signal suppresses the annotation", mode: v1beta1.ReloadModeSignal, want: false},
+		{name: "sidecar suppresses the annotation", mode: v1beta1.ReloadModeSidecar, want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &v1beta1.NodeAgentFluentbit{Reload: &v1beta1.ReloadSettings{Mode: tc.mode}}
+			annotations := podTemplateAnnotations(spec, []byte("config"))
+
+			_, hasHash := annotations[configHashAnnotation]
+			if hasHash != tc.want {
+				t.Errorf("config-hash annotation present = %v, want %v", hasHash, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyReloadSettings_HashesRenderedConfigNotSpec(t *testing.T) {
+	newInstance := func(configs map[string][]byte) *nodeAgentInstance {
+		return &nodeAgentInstance{
+			runtime: fluentbitRuntime{},
+			nodeAgent: &v1beta1.NodeAgent{
+				FluentbitSpec: &v1beta1.NodeAgentFluentbit{},
+			},
+			configs: configs,
+		}
+	}
+
+	unchangedSpec := newInstance(map[string][]byte{"cluster.conf": []byte("[FILTER]\nName a\n")})
+	if err := unchangedSpec.applyReloadSettings(); err != nil {
+		t.Fatalf("applyReloadSettings() error = %v", err)
+	}
+	before := unchangedSpec.nodeAgent.FluentbitSpec.DaemonSetOverrides.Spec.Template.Annotations[configHashAnnotation]
+
+	changedCluster := newInstance(map[string][]byte{"cluster.conf": []byte("[FILTER]\nName b\n")})
+	if err := changedCluster.applyReloadSettings(); err != nil {
+		t.Fatalf("applyReloadSettings() error = %v", err)
+	}
+	after := changedCluster.nodeAgent.FluentbitSpec.DaemonSetOverrides.Spec.Template.Annotations[configHashAnnotation]
+
+	if before == after {
+		t.Errorf("config-hash annotation unchanged (%q) after a cluster filter/parser edit; applyReloadSettings must hash n.configs, not the NodeAgentFluentbit spec", before)
+	}
+}
+
+func TestApplyReloadSettings_Sidecar(t *testing.T) {
+	n := &nodeAgentInstance{
+		runtime: fluentbitRuntime{},
+		nodeAgent: &v1beta1.NodeAgent{
+			FluentbitSpec: &v1beta1.NodeAgentFluentbit{
+				Reload: &v1beta1.ReloadSettings{Mode: v1beta1.ReloadModeSidecar},
+			},
+		},
+	}
+
+	if err := n.applyReloadSettings(); err != nil {
+		t.Fatalf("applyReloadSettings() error = %v", err)
+	}
+
+	tmpl := n.nodeAgent.FluentbitSpec.DaemonSetOverrides.Spec.Template
+	if _, hasHash := tmpl.Annotations[configHashAnnotation]; hasHash {
+		t.Errorf("sidecar mode should not stamp the config-hash annotation, got %v", tmpl.Annotations)
+	}
+
+	found := false
+	for _, c := range tmpl.Spec.Containers {
+		if c.Name == reloaderContainerName {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected reloader sidecar container %q to be appended, got containers %v", reloaderContainerName, tmpl.Spec.Containers)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
+	v1 "k8s.io/api/core/v1"
+)
+
+// NodeAgentRuntime abstracts the log collector deployed by a NodeAgent's
+// DaemonSet, so fluent-bit, vector and the fluentd forwarder can share the
+// same reconcile path instead of fluent-bit being hard-coded everywhere.
+type NodeAgentRuntime interface {
+	// Name identifies the runtime for pod labels, the status map key and similar naming,
+	// e.g. "fluentbit", "vector".
+	Name() string
+	// Defaults returns the runtime specific NodeAgent defaults (image, probes, tail/forward options).
+	Defaults() *v1beta1.NodeAgent
+	// Resources returns the container resource requirements applied to the rendered DaemonSet.
+	Resources() v1.ResourceRequirements
+	// RenderConfig renders the collector configuration from the merged NodeAgent spec.
+	RenderConfig(nodeAgent *v1beta1.NodeAgent) (string, error)
+	// MetricNames returns the Prometheus metric names this runtime's collector exposes for
+	// NodeStatus, and whether its metrics endpoint has a known schema at all. ok is false for
+	// runtimes status.go can't yet interpret, leaving NodeStatus.Phase at
+	// NodeAgentPhaseUnknown instead of guessing from absent metrics.
+	MetricNames() (names RuntimeMetricNames, ok bool)
+}
+
+// RuntimeMetricNames are the Prometheus metric keys scrapePodStatus reads off a NodeAgent
+// pod's metrics endpoint to populate NodeStatus. ReloadFailures/ReloadSuccess are only
+// meaningful for runtimes that support Reload (currently fluent-bit only).
+type RuntimeMetricNames struct {
+	TailOffset     string
+	InputErrors    string
+	OutputErrors   string
+	ReloadFailures string
+	ReloadSuccess  string
+}
+
+// nodeAgentRuntimeFor resolves the NodeAgentRuntime for a NodeAgent, defaulting to fluent-bit
+// for backwards compatibility with specs that don't set Runtime.
+func nodeAgentRuntimeFor(t v1beta1.NodeAgentRuntimeType) (NodeAgentRuntime, error) {
+	switch t {
+	case "", v1beta1.NodeAgentRuntimeFluentbit:
+		return fluentbitRuntime{}, nil
+	case v1beta1.NodeAgentRuntimeVector:
+		return vectorRuntime{}, nil
+	case v1beta1.NodeAgentRuntimeFluentdForwarder:
+		return fluentdForwarderRuntime{}, nil
+	case v1beta1.NodeAgentRuntimeEbpf:
+		return ebpfRuntime{}, nil
+	default:
+		return nil, errors.Errorf("unknown node agent runtime %q", t)
+	}
+}
+
+// isUnlimitedRetry reports whether a ForwardOptions.RetryLimit carries fluent-bit's "False"
+// sentinel for "no retry cap". Other runtimes render their own collector's no-limit form for
+// it instead of pasting the fluent-bit string into a field with different semantics.
+func isUnlimitedRetry(retryLimit string) bool {
+	return strings.EqualFold(retryLimit, "False")
+}
+
+// singleContainerDaemonSetDefaults builds the typeoverride.DaemonSet override shared by the
+// non-fluentbit runtimes, which (like fluent-bit) run a single collector container per node.
+func singleContainerDaemonSetDefaults(containerName, image string, resources v1.ResourceRequirements) *typeoverride.DaemonSet {
+	return &typeoverride.DaemonSet{
+		Spec: typeoverride.DaemonSetSpec{
+			Template: typeoverride.PodTemplateSpec{
+				Spec: typeoverride.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:            containerName,
+							Image:           image,
+							ImagePullPolicy: v1.PullIfNotPresent,
+							Resources:       resources,
+						},
+					},
+				},
+			},
+		},
+	}
+}
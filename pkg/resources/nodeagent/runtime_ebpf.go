@@ -0,0 +1,130 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	ebpfContainerName = "ebpf-agent"
+	bpfFsMountName    = "bpf-fs"
+	bpfFsPath         = "/sys/fs/bpf"
+)
+
+// NodeAgentEbpfDefaults returns the NodeAgent defaults used when Runtime is "ebpf": a
+// host-PID pod granted CAP_BPF/CAP_PERFMON instead of the tailing container the other
+// runtimes use.
+func NodeAgentEbpfDefaults() *v1beta1.NodeAgent {
+	return &v1beta1.NodeAgent{
+		EbpfSpec: &v1beta1.NodeAgentEbpf{
+			DaemonSetOverrides: &typeoverride.DaemonSet{
+				Spec: typeoverride.DaemonSetSpec{
+					Template: typeoverride.PodTemplateSpec{
+						Spec: typeoverride.PodSpec{
+							HostPID: true,
+							Containers: []v1.Container{
+								{
+									Name:            ebpfContainerName,
+									Image:           "banzaicloud/ebpf-agent:0.1.0",
+									ImagePullPolicy: v1.PullIfNotPresent,
+									Resources:       ebpfRuntime{}.Resources(),
+									SecurityContext: &v1.SecurityContext{
+										Capabilities: &v1.Capabilities{
+											Add: []v1.Capability{"CAP_BPF", "CAP_PERFMON"},
+										},
+									},
+									VolumeMounts: []v1.VolumeMount{
+										{
+											Name:      bpfFsMountName,
+											MountPath: bpfFsPath,
+										},
+									},
+								},
+							},
+							Volumes: []v1.Volume{
+								{
+									Name: bpfFsMountName,
+									VolumeSource: v1.VolumeSource{
+										HostPath: &v1.HostPathVolumeSource{
+											Path: bpfFsPath,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			ForwardOptions: &v1beta1.ForwardOptions{
+				RetryLimit: "False",
+			},
+		},
+	}
+}
+
+// ebpfRuntime captures container log writes at the kernel level via a BPF program instead
+// of tailing log files.
+type ebpfRuntime struct{}
+
+func (ebpfRuntime) Name() string {
+	return "ebpf"
+}
+
+func (ebpfRuntime) MetricNames() (RuntimeMetricNames, bool) {
+	return RuntimeMetricNames{}, false
+}
+
+func (ebpfRuntime) Defaults() *v1beta1.NodeAgent {
+	return NodeAgentEbpfDefaults()
+}
+
+func (ebpfRuntime) Resources() v1.ResourceRequirements {
+	return v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("256M"),
+			v1.ResourceCPU:    resource.MustParse("300m"),
+		},
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("128M"),
+			v1.ResourceCPU:    resource.MustParse("150m"),
+		},
+	}
+}
+
+// RenderConfig renders the embedded processor's forwarding config. The capture side has no
+// stanza-based config of its own (it's a compiled BPF program attached at startup); only the
+// forward leg, reusing the same ForwardOptions shape as the other runtimes, is configurable.
+func (ebpfRuntime) RenderConfig(nodeAgent *v1beta1.NodeAgent) (string, error) {
+	spec := nodeAgent.EbpfSpec
+	if spec == nil {
+		return "", errors.New("ebpf runtime selected but NodeAgent.EbpfSpec is nil")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[forward]\n")
+	if spec.ForwardOptions != nil && !isUnlimitedRetry(spec.ForwardOptions.RetryLimit) {
+		fmt.Fprintf(&sb, "retry_limit = %s\n", spec.ForwardOptions.RetryLimit)
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// fluentbitRuntime is the original, default NodeAgentRuntime.
+type fluentbitRuntime struct{}
+
+func (fluentbitRuntime) Name() string {
+	return "fluentbit"
+}
+
+func (fluentbitRuntime) MetricNames() (RuntimeMetricNames, bool) {
+	return RuntimeMetricNames{
+		TailOffset:     "fluentbit_input_tail_offset_bytes",
+		InputErrors:    "fluentbit_input_errors_total",
+		OutputErrors:   "fluentbit_output_errors_total",
+		ReloadFailures: "fluentbit_reloader_failures_total",
+		ReloadSuccess:  "fluentbit_reloader_success_total",
+	}, true
+}
+
+func (fluentbitRuntime) Defaults() *v1beta1.NodeAgent {
+	return NodeAgentFluentbitDefaults()
+}
+
+func (fluentbitRuntime) Resources() v1.ResourceRequirements {
+	return NodeAgentFluentbitDefaults().FluentbitSpec.DaemonSetOverrides.Spec.Template.Spec.Containers[0].Resources
+}
+
+// RenderConfig is a no-op for fluent-bit: its configuration is assembled by configSecret
+// directly from the FluentbitSpec, predating the NodeAgentRuntime abstraction.
+func (fluentbitRuntime) RenderConfig(nodeAgent *v1beta1.NodeAgent) (string, error) {
+	return "", nil
+}
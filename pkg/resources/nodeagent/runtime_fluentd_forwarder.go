@@ -0,0 +1,111 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const fluentdForwarderContainerName = "fluentd-forwarder"
+
+// NodeAgentFluentdForwarderDefaults returns the NodeAgent defaults used when Runtime is "fluentd-forwarder".
+func NodeAgentFluentdForwarderDefaults() *v1beta1.NodeAgent {
+	return &v1beta1.NodeAgent{
+		FluentdForwarderSpec: &v1beta1.NodeAgentFluentdForwarder{
+			DaemonSetOverrides: singleContainerDaemonSetDefaults(fluentdForwarderContainerName, "fluent/fluentd:v1.14-1", fluentdForwarderRuntime{}.Resources()),
+			InputTail: v1beta1.InputTail{
+				Path:            "/var/log/containers/*.log",
+				RefreshInterval: "5",
+				SkipLongLines:   "On",
+				DB:              util.StringPointer("/tail-db/tail-containers-state.db"),
+				MemBufLimit:     "5MB",
+				Tag:             "kubernetes.*",
+			},
+			ForwardOptions: &v1beta1.ForwardOptions{
+				RetryLimit: "False",
+			},
+		},
+	}
+}
+
+// fluentdForwarderRuntime renders an "in_tail" source + "out_forward" match pair, forwarding
+// to the same aggregator the other runtimes use via ForwardOptions.
+type fluentdForwarderRuntime struct{}
+
+func (fluentdForwarderRuntime) Name() string {
+	return "fluentd-forwarder"
+}
+
+func (fluentdForwarderRuntime) MetricNames() (RuntimeMetricNames, bool) {
+	return RuntimeMetricNames{}, false
+}
+
+func (fluentdForwarderRuntime) Defaults() *v1beta1.NodeAgent {
+	return NodeAgentFluentdForwarderDefaults()
+}
+
+func (fluentdForwarderRuntime) Resources() v1.ResourceRequirements {
+	return v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("100M"),
+			v1.ResourceCPU:    resource.MustParse("200m"),
+		},
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("50M"),
+			v1.ResourceCPU:    resource.MustParse("100m"),
+		},
+	}
+}
+
+func (fluentdForwarderRuntime) RenderConfig(nodeAgent *v1beta1.NodeAgent) (string, error) {
+	spec := nodeAgent.FluentdForwarderSpec
+	if spec == nil {
+		return "", errors.New("fluentd-forwarder runtime selected but NodeAgent.FluentdForwarderSpec is nil")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("<source>\n")
+	sb.WriteString("  @type tail\n")
+	fmt.Fprintf(&sb, "  path %s\n", spec.InputTail.Path)
+	fmt.Fprintf(&sb, "  tag %s\n", spec.InputTail.Tag)
+	fmt.Fprintf(&sb, "  refresh_interval %s\n", spec.InputTail.RefreshInterval)
+	if spec.InputTail.DB != nil {
+		fmt.Fprintf(&sb, "  pos_file %s\n", *spec.InputTail.DB)
+	}
+	sb.WriteString("</source>\n\n")
+
+	sb.WriteString("<match kubernetes.**>\n")
+	sb.WriteString("  @type forward\n")
+	if spec.ForwardOptions != nil {
+		sb.WriteString("  <buffer>\n")
+		if isUnlimitedRetry(spec.ForwardOptions.RetryLimit) {
+			sb.WriteString("    retry_forever true\n")
+		} else {
+			fmt.Fprintf(&sb, "    retry_max_times %s\n", spec.ForwardOptions.RetryLimit)
+		}
+		sb.WriteString("  </buffer>\n")
+	}
+	sb.WriteString("</match>\n")
+
+	return sb.String(), nil
+}
@@ -0,0 +1,118 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+)
+
+func TestVectorRuntimeRenderConfig_RetryLimit(t *testing.T) {
+	cases := []struct {
+		name       string
+		retryLimit string
+		wantKey    string
+	}{
+		{name: "unlimited omits the key", retryLimit: "False", wantKey: ""},
+		{name: "bounded sets retry_attempts", retryLimit: "5", wantKey: "request.retry_attempts = 5"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodeAgent := vectorRuntime{}.Defaults()
+			nodeAgent.VectorSpec.ForwardOptions = &v1beta1.ForwardOptions{RetryLimit: tc.retryLimit}
+
+			config, err := vectorRuntime{}.RenderConfig(nodeAgent)
+			if err != nil {
+				t.Fatalf("RenderConfig() error = %v", err)
+			}
+			if strings.Contains(config, "retry_max_duration_secs") {
+				t.Errorf("config still pastes the fluent-bit RetryLimit string into a duration field:\n%s", config)
+			}
+			if tc.wantKey != "" && !strings.Contains(config, tc.wantKey) {
+				t.Errorf("expected config to contain %q, got:\n%s", tc.wantKey, config)
+			}
+			if tc.wantKey == "" && strings.Contains(config, "retry_attempts") {
+				t.Errorf("expected no retry_attempts key for an unlimited RetryLimit, got:\n%s", config)
+			}
+		})
+	}
+}
+
+func TestEbpfRuntimeRenderConfig_RetryLimit(t *testing.T) {
+	cases := []struct {
+		name       string
+		retryLimit string
+		wantKey    string
+	}{
+		{name: "unlimited omits the key", retryLimit: "False", wantKey: ""},
+		{name: "bounded sets retry_limit", retryLimit: "5", wantKey: "retry_limit = 5"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodeAgent := ebpfRuntime{}.Defaults()
+			nodeAgent.EbpfSpec.ForwardOptions = &v1beta1.ForwardOptions{RetryLimit: tc.retryLimit}
+
+			config, err := ebpfRuntime{}.RenderConfig(nodeAgent)
+			if err != nil {
+				t.Fatalf("RenderConfig() error = %v", err)
+			}
+			if strings.Contains(config, "False") {
+				t.Errorf("config still pastes the fluent-bit RetryLimit sentinel verbatim:\n%s", config)
+			}
+			if tc.wantKey != "" && !strings.Contains(config, tc.wantKey) {
+				t.Errorf("expected config to contain %q, got:\n%s", tc.wantKey, config)
+			}
+			if tc.wantKey == "" && strings.Contains(config, "retry_limit") {
+				t.Errorf("expected no retry_limit key for an unlimited RetryLimit, got:\n%s", config)
+			}
+		})
+	}
+}
+
+func TestFluentdForwarderRuntimeRenderConfig_RetryLimit(t *testing.T) {
+	cases := []struct {
+		name       string
+		retryLimit string
+		want       string
+	}{
+		{name: "unlimited retries forever", retryLimit: "False", want: "retry_forever true"},
+		{name: "bounded sets retry_max_times", retryLimit: "3", want: "retry_max_times 3"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodeAgent := fluentdForwarderRuntime{}.Defaults()
+			nodeAgent.FluentdForwarderSpec.ForwardOptions = &v1beta1.ForwardOptions{RetryLimit: tc.retryLimit}
+
+			config, err := fluentdForwarderRuntime{}.RenderConfig(nodeAgent)
+			if err != nil {
+				t.Fatalf("RenderConfig() error = %v", err)
+			}
+			if !strings.Contains(config, "<buffer>") {
+				t.Errorf("expected retry settings inside a <buffer> block, got:\n%s", config)
+			}
+			if !strings.Contains(config, tc.want) {
+				t.Errorf("expected config to contain %q, got:\n%s", tc.want, config)
+			}
+			if strings.Contains(config, "retry_limit") {
+				t.Errorf("config still contains the bare fluent-bit retry_limit key:\n%s", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const vectorContainerName = "vector"
+
+// NodeAgentVectorDefaults returns the NodeAgent defaults used when Runtime is "vector".
+func NodeAgentVectorDefaults() *v1beta1.NodeAgent {
+	return &v1beta1.NodeAgent{
+		VectorSpec: &v1beta1.NodeAgentVector{
+			DaemonSetOverrides: singleContainerDaemonSetDefaults(vectorContainerName, "timberio/vector:0.17.0-distroless-libc", vectorRuntime{}.Resources()),
+			InputTail: v1beta1.InputTail{
+				Path:            "/var/log/containers/*.log",
+				RefreshInterval: "5",
+				SkipLongLines:   "On",
+				DB:              util.StringPointer("/tail-db/tail-containers-state.db"),
+				MemBufLimit:     "5MB",
+				Tag:             "kubernetes.*",
+			},
+			FilterAws: &v1beta1.FilterAws{
+				ImdsVersion: "v2",
+				AZ:          util.BoolPointer(true),
+				Match:       "*",
+			},
+			ForwardOptions: &v1beta1.ForwardOptions{
+				RetryLimit: "False",
+			},
+		},
+	}
+}
+
+// vectorRuntime renders a Vector "sources.kubernetes_logs" + "sinks.vector" pipeline
+// from the same InputTail/FilterAws/ForwardOptions shape fluent-bit uses.
+type vectorRuntime struct{}
+
+func (vectorRuntime) Name() string {
+	return "vector"
+}
+
+func (vectorRuntime) MetricNames() (RuntimeMetricNames, bool) {
+	return RuntimeMetricNames{}, false
+}
+
+func (vectorRuntime) Defaults() *v1beta1.NodeAgent {
+	return NodeAgentVectorDefaults()
+}
+
+func (vectorRuntime) Resources() v1.ResourceRequirements {
+	return v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("150M"),
+			v1.ResourceCPU:    resource.MustParse("200m"),
+		},
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("75M"),
+			v1.ResourceCPU:    resource.MustParse("100m"),
+		},
+	}
+}
+
+func (vectorRuntime) RenderConfig(nodeAgent *v1beta1.NodeAgent) (string, error) {
+	spec := nodeAgent.VectorSpec
+	if spec == nil {
+		return "", errors.New("vector runtime selected but NodeAgent.VectorSpec is nil")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("[sources.kubernetes_logs]\n")
+	sb.WriteString("type = \"kubernetes_logs\"\n")
+	fmt.Fprintf(&sb, "include_paths_glob_patterns = [%q]\n", spec.InputTail.Path)
+	if spec.InputTail.DB != nil {
+		fmt.Fprintf(&sb, "data_dir = %q\n", *spec.InputTail.DB)
+	}
+	sb.WriteString("\n")
+
+	sinkInput := "kubernetes_logs"
+	if spec.FilterAws != nil {
+		sb.WriteString("[transforms.aws_metadata]\n")
+		sb.WriteString("type = \"aws_ec2_metadata\"\n")
+		sb.WriteString("inputs = [\"kubernetes_logs\"]\n")
+		fmt.Fprintf(&sb, "imds_version = %q\n", spec.FilterAws.ImdsVersion)
+		sb.WriteString("\n")
+		sinkInput = "aws_metadata"
+	}
+
+	sb.WriteString("[sinks.vector]\n")
+	sb.WriteString("type = \"vector\"\n")
+	fmt.Fprintf(&sb, "inputs = [%q]\n", sinkInput)
+	if spec.ForwardOptions != nil && !isUnlimitedRetry(spec.ForwardOptions.RetryLimit) {
+		// Vector retries indefinitely by default, so the unlimited case is the absence of
+		// this key rather than a value; only a bounded RetryLimit needs to be rendered.
+		fmt.Fprintf(&sb, "request.retry_attempts = %s\n", spec.ForwardOptions.RetryLimit)
+	}
+
+	return sb.String(), nil
+}
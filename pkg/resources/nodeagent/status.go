@@ -0,0 +1,183 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// metricsPort/metricsPath are scraped from each NodeAgent pod to build its NodeStatus.
+const (
+	metricsPort = 2020
+	metricsPath = "/api/v1/metrics/prometheus"
+
+	// metricsRequestTimeout bounds each per-pod scrape so one unreachable/hung metrics
+	// endpoint can't block the whole Reconcile call.
+	metricsRequestTimeout = 2 * time.Second
+)
+
+// metricsHTTPClient is shared across scrapePodStatus calls; the default client has no
+// timeout, which is unsafe to use against a pod-controlled endpoint.
+var metricsHTTPClient = &http.Client{Timeout: metricsRequestTimeout}
+
+// collectStatus lists the pods owned by this NodeAgent's DaemonSet, scrapes each one's
+// metrics endpoint, and returns the aggregated status, modeled after ONAP's
+// ResourceBundleState: a small typed status kept current by a periodic, informer-driven
+// collector rather than requiring users to query Prometheus themselves.
+func (n *nodeAgentInstance) collectStatus(ctx context.Context) (*v1beta1.NodeAgentStatus, error) {
+	var pods corev1.PodList
+	if err := n.client.List(ctx, &pods,
+		client.InNamespace(n.logging.Spec.ControlNamespace),
+		client.MatchingLabels(n.getRuntimeLabels())); err != nil {
+		return nil, errors.WrapIf(err, "failed to list node agent pods")
+	}
+
+	status := &v1beta1.NodeAgentStatus{
+		Nodes: make(map[string]v1beta1.NodeStatus, len(pods.Items)),
+	}
+	for _, pod := range pods.Items {
+		status.Nodes[pod.Spec.NodeName] = n.scrapePodStatus(pod)
+	}
+
+	return status, nil
+}
+
+// scrapePodStatus scrapes a single pod's Prometheus metrics endpoint and turns it into a
+// NodeStatus, using the metric names of the NodeAgent's active runtime. Runtimes with no
+// known metrics schema (ok=false) are left at NodeAgentPhaseUnknown rather than guessing
+// health from metric keys that will never be present.
+func (n *nodeAgentInstance) scrapePodStatus(pod corev1.Pod) v1beta1.NodeStatus {
+	status := v1beta1.NodeStatus{
+		Phase: v1beta1.NodeAgentPhaseUnknown,
+	}
+
+	names, ok := n.runtime.MetricNames()
+	if !ok || pod.Status.PodIP == "" {
+		return status
+	}
+
+	resp, err := metricsHTTPClient.Get(fmt.Sprintf("http://%s:%d%s", pod.Status.PodIP, metricsPort, metricsPath))
+	if err != nil {
+		return status
+	}
+	defer resp.Body.Close()
+
+	metrics, err := parsePrometheusMetrics(resp.Body)
+	if err != nil {
+		return status
+	}
+
+	status.LastHeartbeat = metav1.NewTime(time.Now())
+	status.TailDBPosition = int64(metrics[names.TailOffset])
+	status.InputErrors = int64(metrics[names.InputErrors])
+	status.OutputErrors = int64(metrics[names.OutputErrors])
+	status.ReloadPhase = reloadPhaseFromMetrics(metrics, names)
+
+	switch {
+	case status.OutputErrors > 0:
+		status.Phase = v1beta1.NodeAgentPhaseBackpressure
+	case status.InputErrors > 0:
+		status.Phase = v1beta1.NodeAgentPhaseLagging
+	default:
+		status.Phase = v1beta1.NodeAgentPhaseHealthy
+	}
+
+	return status
+}
+
+// parsePrometheusMetrics parses the subset of the Prometheus text exposition format that
+// metricsPath returns: "# HELP"/"# TYPE" comment lines are skipped, and each remaining line
+// is "metric_name{labels} value" or "metric_name value". Label values are discarded; callers
+// only ever look up bare metric names.
+func parsePrometheusMetrics(r io.Reader) (map[string]float64, error) {
+	metrics := make(map[string]float64)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		name := fields[0]
+		if idx := strings.IndexByte(name, '{'); idx >= 0 {
+			name = name[:idx]
+		}
+
+		value, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil {
+			continue
+		}
+		metrics[name] = value
+	}
+
+	return metrics, scanner.Err()
+}
+
+// reloadPhaseFromMetrics derives a signal/sidecar reload outcome from the scraped cumulative
+// reload counters, so it shows up next to the rest of a node's health in the status
+// subresource instead of only being visible in Prometheus: Failed when there is at least one
+// failure and failures are at least as many as successes, OK otherwise. It compares totals,
+// so it does not tell which reload happened last. Runtimes without reload metric names (the
+// zero value of names.ReloadFailures/ReloadSuccess) always report Unknown.
+func reloadPhaseFromMetrics(metrics map[string]float64, names RuntimeMetricNames) v1beta1.ReloadPhase {
+	if names.ReloadFailures == "" && names.ReloadSuccess == "" {
+		return v1beta1.ReloadPhaseUnknown
+	}
+	failures, hasFailures := metrics[names.ReloadFailures]
+	successes, hasSuccesses := metrics[names.ReloadSuccess]
+	if !hasFailures && !hasSuccesses {
+		return v1beta1.ReloadPhaseUnknown
+	}
+	if failures > 0 && failures >= successes {
+		return v1beta1.ReloadPhaseFailed
+	}
+	return v1beta1.ReloadPhaseOK
+}
+
+// syncStatus collects this NodeAgent's status and writes it back onto the Logging CR's
+// status subresource, keyed by QualifiedName and the active runtime's name so NodeAgents
+// running different runtimes don't share a status key.
+func (n *nodeAgentInstance) syncStatus(ctx context.Context) error {
+	status, err := n.collectStatus(ctx)
+	if err != nil {
+		return errors.WrapIf(err, "failed to collect node agent status")
+	}
+
+	if n.logging.Status.NodeAgents == nil {
+		n.logging.Status.NodeAgents = make(map[string]v1beta1.NodeAgentStatus)
+	}
+	n.logging.Status.NodeAgents[n.QualifiedName(n.runtime.Name())] = *status
+
+	return errors.WrapIf(n.client.Status().Update(ctx, n.logging), "failed to update logging status")
+}
@@ -0,0 +1,136 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParsePrometheusMetrics(t *testing.T) {
+	input := `# HELP fluentbit_input_tail_offset_bytes tail offset
+# TYPE fluentbit_input_tail_offset_bytes counter
+fluentbit_input_tail_offset_bytes{name="tail.0"} 42
+fluentbit_output_errors_total 0
+fluentbit_input_errors_total 3
+`
+
+	metrics, err := parsePrometheusMetrics(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parsePrometheusMetrics() error = %v", err)
+	}
+
+	want := map[string]float64{
+		"fluentbit_input_tail_offset_bytes": 42,
+		"fluentbit_output_errors_total":     0,
+		"fluentbit_input_errors_total":      3,
+	}
+	for name, value := range want {
+		if metrics[name] != value {
+			t.Errorf("metrics[%q] = %v, want %v", name, metrics[name], value)
+		}
+	}
+}
+
+func TestScrapePodStatus_ParsesPrometheusTextFormat(t *testing.T) {
+	names, _ := fluentbitRuntime{}.MetricNames()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(metricsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s{name=\"tail.0\"} 42\n%s 0\n%s 0\n",
+			names.TailOffset, names.InputErrors, names.OutputErrors)
+	})
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", metricsPort))
+	if err != nil {
+		t.Skipf("metrics port %d unavailable: %v", metricsPort, err)
+	}
+	server := httptest.Server{Listener: listener, Config: &http.Server{Handler: mux}}
+	server.Start()
+	defer server.Close()
+
+	n := &nodeAgentInstance{runtime: fluentbitRuntime{}}
+	pod := corev1.Pod{Status: corev1.PodStatus{PodIP: "127.0.0.1"}}
+
+	status := n.scrapePodStatus(pod)
+	if status.TailDBPosition != 42 {
+		t.Errorf("TailDBPosition = %d, want 42", status.TailDBPosition)
+	}
+	if status.Phase != v1beta1.NodeAgentPhaseHealthy {
+		t.Errorf("Phase = %v, want %v", status.Phase, v1beta1.NodeAgentPhaseHealthy)
+	}
+}
+
+func TestReloadPhaseFromMetrics(t *testing.T) {
+	fluentbitNames, _ := fluentbitRuntime{}.MetricNames()
+	vectorNames, _ := vectorRuntime{}.MetricNames()
+
+	cases := []struct {
+		name    string
+		metrics map[string]float64
+		names   RuntimeMetricNames
+		want    v1beta1.ReloadPhase
+	}{
+		{
+			name:    "runtime without reload metric names is always unknown",
+			metrics: map[string]float64{"fluentbit_reloader_failures_total": 5},
+			names:   vectorNames,
+			want:    v1beta1.ReloadPhaseUnknown,
+		},
+		{
+			name:    "no metrics scraped yet",
+			metrics: map[string]float64{},
+			names:   fluentbitNames,
+			want:    v1beta1.ReloadPhaseUnknown,
+		},
+		{
+			name:    "failures outnumber successes",
+			metrics: map[string]float64{"fluentbit_reloader_failures_total": 2, "fluentbit_reloader_success_total": 1},
+			names:   fluentbitNames,
+			want:    v1beta1.ReloadPhaseFailed,
+		},
+		{
+			name:    "successes outnumber failures",
+			metrics: map[string]float64{"fluentbit_reloader_failures_total": 1, "fluentbit_reloader_success_total": 2},
+			names:   fluentbitNames,
+			want:    v1beta1.ReloadPhaseOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := reloadPhaseFromMetrics(tc.metrics, tc.names); got != tc.want {
+				t.Errorf("reloadPhaseFromMetrics() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScrapePodStatus_UnknownRuntimeMetrics(t *testing.T) {
+	n := &nodeAgentInstance{runtime: vectorRuntime{}}
+	pod := corev1.Pod{Status: corev1.PodStatus{PodIP: "10.0.0.1"}}
+
+	status := n.scrapePodStatus(pod)
+	if status.Phase != v1beta1.NodeAgentPhaseUnknown {
+		t.Errorf("Phase = %v, want %v for a runtime with no known metrics schema", status.Phase, v1beta1.NodeAgentPhaseUnknown)
+	}
+}